@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BMNode is the subset of a BareMetalHost custom resource this tool cares
+// about, parsed from a bm-node-*.yaml manifest.
+type BMNode struct {
+	Name           string            `json:"name"`
+	Annotations    map[string]string `json:"annotations"`
+	Labels         map[string]string `json:"labels"`
+	BMCAddress     string            `json:"bmcAddress"`
+	BootMACAddress string            `json:"bootMACAddress"`
+	Online         bool              `json:"online"`
+}
+
+// hostnameAnnotation is the annotation key that carries the agent-install
+// hostname, used to keep SiteHostnameInfo.Hostnames populated.
+const hostnameAnnotation = "bmac.agent-install.openshift.io/hostname"
+
+// bareMetalHost mirrors the fields of a BareMetalHost YAML manifest that
+// BMNode is built from.
+type bareMetalHost struct {
+	Metadata struct {
+		Name        string            `yaml:"name"`
+		Annotations map[string]string `yaml:"annotations"`
+		Labels      map[string]string `yaml:"labels"`
+	} `yaml:"metadata"`
+	Spec struct {
+		BMC struct {
+			Address string `yaml:"address"`
+		} `yaml:"bmc"`
+		BootMACAddress string `yaml:"bootMACAddress"`
+		Online         bool   `yaml:"online"`
+	} `yaml:"spec"`
+}
+
+// parseBMNode decodes the full YAML body of a bm-node-*.yaml file into a
+// BMNode. Unlike a regex match against a single annotation, this correctly
+// handles annotations written across multiple lines or using YAML
+// block-scalar syntax, and exposes the rest of the manifest besides.
+func parseBMNode(content []byte) (BMNode, error) {
+	var host bareMetalHost
+	if err := yaml.Unmarshal(content, &host); err != nil {
+		return BMNode{}, fmt.Errorf("parsing BareMetalHost YAML: %w", err)
+	}
+
+	return BMNode{
+		Name:           host.Metadata.Name,
+		Annotations:    host.Metadata.Annotations,
+		Labels:         host.Metadata.Labels,
+		BMCAddress:     host.Spec.BMC.Address,
+		BootMACAddress: host.Spec.BootMACAddress,
+		Online:         host.Spec.Online,
+	}, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// responseCache memoizes Gitea API responses so that repeated SSE
+// connections don't have to re-walk and re-download the whole repository
+// tree. Entries are valid for ttl; once that elapses, file fetches
+// revalidate against Gitea with If-None-Match/If-Modified-Since so an
+// unchanged file costs only a cheap 304, and directory listings are skipped
+// entirely when the branch's head commit hasn't moved.
+type responseCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+
+	files  map[string]*fileCacheEntry
+	dirs   map[string]*dirCacheEntry
+	branch map[string]*branchCacheEntry
+}
+
+// fileCacheEntry holds a cached raw file body plus the validators needed to
+// cheaply revalidate it.
+type fileCacheEntry struct {
+	body         []byte
+	etag         string
+	lastModified string
+	fetchedAt    time.Time
+}
+
+// dirCacheEntry holds a cached directory listing.
+type dirCacheEntry struct {
+	items     []SourceEntry
+	fetchedAt time.Time
+}
+
+// branchCacheEntry holds the last known head-commit timestamp for a branch.
+type branchCacheEntry struct {
+	timestamp time.Time
+	fetchedAt time.Time
+}
+
+func newResponseCache(ttl time.Duration, maxEntries int) *responseCache {
+	return &responseCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		files:      make(map[string]*fileCacheEntry),
+		dirs:       make(map[string]*dirCacheEntry),
+		branch:     make(map[string]*branchCacheEntry),
+	}
+}
+
+func (rc *responseCache) getFile(key string) (*fileCacheEntry, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	e, ok := rc.files[key]
+	return e, ok
+}
+
+func (rc *responseCache) putFile(key string, e *fileCacheEntry) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if _, exists := rc.files[key]; !exists && len(rc.files) >= rc.maxEntries {
+		evictOldest(rc.files)
+	}
+	rc.files[key] = e
+}
+
+func (rc *responseCache) getDir(key string) (*dirCacheEntry, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	e, ok := rc.dirs[key]
+	return e, ok
+}
+
+func (rc *responseCache) putDir(key string, e *dirCacheEntry) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if _, exists := rc.dirs[key]; !exists && len(rc.dirs) >= rc.maxEntries {
+		evictOldest(rc.dirs)
+	}
+	rc.dirs[key] = e
+}
+
+func (rc *responseCache) getBranch(key string) (*branchCacheEntry, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	e, ok := rc.branch[key]
+	return e, ok
+}
+
+func (rc *responseCache) putBranch(key string, e *branchCacheEntry) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if _, exists := rc.branch[key]; !exists && len(rc.branch) >= rc.maxEntries {
+		evictOldest(rc.branch)
+	}
+	rc.branch[key] = e
+}
+
+// fetchedAter is implemented by every cache entry type so evictOldest can
+// work generically across the file/dir/branch maps.
+type fetchedAter interface {
+	fetchedTime() time.Time
+}
+
+func (e *fileCacheEntry) fetchedTime() time.Time   { return e.fetchedAt }
+func (e *dirCacheEntry) fetchedTime() time.Time    { return e.fetchedAt }
+func (e *branchCacheEntry) fetchedTime() time.Time { return e.fetchedAt }
+
+// evictOldest drops the least-recently-fetched entry from m, bounding the
+// cache's memory use once maxEntries is reached.
+func evictOldest[V fetchedAter](m map[string]V) {
+	var oldestKey string
+	var oldestAt time.Time
+	for k, v := range m {
+		if oldestKey == "" || v.fetchedTime().Before(oldestAt) {
+			oldestKey = k
+			oldestAt = v.fetchedTime()
+		}
+	}
+	if oldestKey != "" {
+		delete(m, oldestKey)
+	}
+}
@@ -1,140 +1,392 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"flag"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"log"
 	"net/http"
+	"os"
 	"regexp"
 	"strings"
 	"sync"
 	"time"
+
+	"code.gitea.io/sdk/gitea"
+	"golang.org/x/sync/semaphore"
 )
 
-// SiteHostnameInfo stores the extracted information for a site.
+// SiteHostnameInfo stores the extracted information for a site. Nodes holds
+// the fully parsed BareMetalHost data; Hostnames is kept populated from
+// Nodes' hostname annotation for backward compatibility with existing
+// consumers of this field.
 type SiteHostnameInfo struct {
+	Source      string   `json:"source"`
 	SitePath    string   `json:"sitePath"`
 	Hostnames   []string `json:"hostnames"`
+	Nodes       []BMNode `json:"nodes"`
 	BMNodeFiles []string `json:"bmNodeFiles"`
 	Errors      []string `json:"errors"`
 }
 
-// GiteaFile represents a file or directory in a Gitea repository.
-type GiteaFile struct {
-	Name        string `json:"name"`
-	Type        string `json:"type"`
-	DownloadURL string `json:"download_url"`
+var bmNodeFileRegex = regexp.MustCompile(`bm-node-.+\.yaml$`)
+
+// GiteaClient implements SiteSource against the official Gitea SDK, pinned
+// to a single owner/repo/branch. A fresh *gitea.Client is built per call
+// rather than shared across goroutines: the SDK's SetContext mutates a
+// single ctx field on the *gitea.Client instance it's called on, so handing
+// one long-lived client to concurrent goroutines would have them race on
+// each other's context. Building one per call isn't as expensive as it
+// sounds - SetGiteaVersion("") skips the SDK's usual server-version probe,
+// so all NewClient does is allocate. A weighted semaphore caps how many
+// ListDir/FetchFile calls may be in flight at once so a large repository
+// tree can't fan out into an unbounded number of concurrent requests against
+// the Gitea server, and a responseCache lets repeated scans skip work that
+// Gitea's own data hasn't changed since.
+type GiteaClient struct {
+	httpClient *http.Client
+	baseURL    string
+	owner      string
+	repo       string
+	branch     string
+	token      string
+	name       string
+	sem        *semaphore.Weighted
+	cache      *responseCache
 }
 
-var (
-	bmNodeFileRegex = regexp.MustCompile(`bm-node-.+\.yaml$`)
-	hostnameRegex   = regexp.MustCompile(`bmac\.agent-install\.openshift\.io/hostname:\s*["']?([^
-"'\s]+)["']?`)
-)
+// NewGiteaClient builds a GiteaClient for sc, authenticating with sc.Token
+// (may be empty for public repos) over the given transport. Passing a
+// transport lets callers control TLS behavior instead of relying on a
+// hard-coded InsecureSkipVerify. maxConcurrentRequests bounds how many
+// ListDir/FetchFile calls may run at the same time; cacheTTL and
+// cacheMaxEntries size the response cache.
+func NewGiteaClient(sc SourceConfig, transport *http.Transport, maxConcurrentRequests int64, cacheTTL time.Duration, cacheMaxEntries int) (*GiteaClient, error) {
+	if sc.BaseURL == "" || sc.Owner == "" || sc.Repo == "" {
+		return nil, fmt.Errorf("gitea source requires baseURL, owner and repo")
+	}
+	branch := sc.Branch
+	if branch == "" {
+		branch = "prod"
+	}
 
-func main() {
-	http.HandleFunc("/api/data", dataHandler)
-	fmt.Println("Server is running on http://localhost:3001")
-	log.Fatal(http.ListenAndServe(":3001", nil))
+	return &GiteaClient{
+		httpClient: &http.Client{Transport: transport, Timeout: 15 * time.Second},
+		baseURL:    sc.BaseURL,
+		owner:      sc.Owner,
+		repo:       sc.Repo,
+		branch:     branch,
+		token:      sc.Token,
+		name:       fmt.Sprintf("gitea:%s/%s@%s", sc.Owner, sc.Repo, branch),
+		sem:        semaphore.NewWeighted(maxConcurrentRequests),
+		cache:      newResponseCache(cacheTTL, cacheMaxEntries),
+	}, nil
 }
 
-func dataHandler(w http.ResponseWriter, r *http.Request) {
-	log.Println("Received request for /api/data SSE stream")
+// Name identifies this source in SiteHostnameInfo.Source.
+func (c *GiteaClient) Name() string {
+	return c.name
+}
 
-	// Set headers for SSE
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
+// sdkClient builds a *gitea.Client scoped to ctx. Call sites build one per
+// request rather than reusing a shared client so that ctx cancellation
+// (e.g. the SSE client disconnecting) only ever affects the call that
+// owns it.
+func (c *GiteaClient) sdkClient(ctx context.Context) (*gitea.Client, error) {
+	return gitea.NewClient(c.baseURL,
+		gitea.SetHTTPClient(c.httpClient),
+		gitea.SetToken(c.token),
+		gitea.SetContext(ctx),
+		gitea.SetGiteaVersion(""),
+	)
+}
 
-	flusher, ok := w.(http.Flusher)
-	if !ok {
-		http.Error(w, "Streaming unsupported!", http.StatusInternalServerError)
-		return
+// ListDir returns the directory listing for path (path == "" means the
+// repository root). If the branch's head commit hasn't moved since the last
+// call, a cached listing is returned without hitting Gitea at all.
+func (c *GiteaClient) ListDir(ctx context.Context, path string) ([]SourceEntry, error) {
+	changed, err := c.branchChangedSinceCache(ctx)
+	if err == nil && !changed {
+		if entry, ok := c.cache.getDir(path); ok {
+			return entry.items, nil
+		}
 	}
 
-	repoURL := "https://codeview.jio.indradhanus.com/indradhanus/sites"
-	branch := "prod"
-	
-	siteInfoChan := make(chan SiteHostnameInfo)
+	if err := c.sem.Acquire(ctx, 1); err != nil {
+		return nil, err
+	}
+	defer c.sem.Release(1)
 
-	// Start fetching data in a new goroutine
-	go func() {
-		defer close(siteInfoChan) // Close channel when done
-		extractHostnamesFromGiteaRepository(repoURL, branch, siteInfoChan)
-	}()
+	sdk, err := c.sdkClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("creating gitea client: %w", err)
+	}
 
-	// Listen for new site info and send it to the client
-	for siteInfo := range siteInfoChan {
-		jsonData, err := json.Marshal(siteInfo)
-		if err != nil {
-			log.Printf("Error marshalling JSON: %v", err)
-			continue
-		}
-		// Format as an SSE message
-		fmt.Fprintf(w, "data: %s\n\n", jsonData)
-		flusher.Flush() // Flush the data to the client
+	contents, _, err := sdk.ListContents(c.owner, c.repo, c.branch, path)
+	if err != nil {
+		// Gitea returns a 404/422 for a path that names a file rather than a
+		// directory; callers treat that as "not a directory" rather than a
+		// hard error.
+		return nil, nil
 	}
 
-	log.Println("Finished streaming data.")
+	items := make([]SourceEntry, len(contents))
+	for i, item := range contents {
+		items[i] = SourceEntry{Name: item.Name, Type: string(item.Type)}
+	}
+
+	c.cache.putDir(path, &dirCacheEntry{items: items, fetchedAt: time.Now()})
+	return items, nil
 }
 
+// branchChangedSinceCache reports whether the branch's head commit
+// timestamp has moved since the last time it was observed, refreshing the
+// cached timestamp as a side effect. A cached "no change" verdict is itself
+// only trusted for the cache's TTL, so a long-lived server still notices new
+// commits eventually.
+func (c *GiteaClient) branchChangedSinceCache(ctx context.Context) (bool, error) {
+	if entry, ok := c.cache.getBranch(c.branch); ok && time.Since(entry.fetchedAt) < c.cache.ttl {
+		return false, nil
+	}
 
-func fetchURL(url string) ([]byte, error) {
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: true,
-			MinVersion:         tls.VersionTLS12,
-			MaxVersion:         tls.VersionTLS12,
-		},
+	if err := c.sem.Acquire(ctx, 1); err != nil {
+		return false, err
+	}
+	defer c.sem.Release(1)
+
+	sdk, err := c.sdkClient(ctx)
+	if err != nil {
+		return false, fmt.Errorf("creating gitea client: %w", err)
 	}
-	client := &http.Client{Transport: tr, Timeout: 15 * time.Second}
 
-	resp, err := client.Get(url)
+	b, _, err := sdk.GetRepoBranch(c.owner, c.repo, c.branch)
 	if err != nil {
+		return false, err
+	}
+
+	var head time.Time
+	if b.Commit != nil {
+		head = b.Commit.Timestamp
+	}
+
+	prev, hadPrev := c.cache.getBranch(c.branch)
+	c.cache.putBranch(c.branch, &branchCacheEntry{timestamp: head, fetchedAt: time.Now()})
+
+	return !hadPrev || !prev.timestamp.Equal(head), nil
+}
+
+// FetchFile downloads the raw bytes of path. A fresh cache entry is
+// returned as-is; a stale one is revalidated against Gitea with
+// If-None-Match/If-Modified-Since so an unchanged file costs only a 304.
+// The SDK's GetFile has no way to pass those conditional headers, so this
+// one call talks to Gitea's raw HTTP endpoint directly instead of going
+// through sdkClient.
+func (c *GiteaClient) FetchFile(ctx context.Context, path string) ([]byte, error) {
+	rawURL := rawFileURL(c.baseURL, c.owner, c.repo, path, c.branch)
+
+	cached, haveCached := c.cache.getFile(rawURL)
+	if haveCached && time.Since(cached.fetchedAt) < c.cache.ttl {
+		return cached.body, nil
+	}
+
+	if err := c.sem.Acquire(ctx, 1); err != nil {
 		return nil, err
 	}
+	defer c.sem.Release(1)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("building request for %s: %w", rawURL, err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "token "+c.token)
+	}
+	if haveCached {
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s@%s: %w", path, c.branch, err)
+	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && haveCached {
+		cached.fetchedAt = time.Now()
+		c.cache.putFile(rawURL, cached)
+		return cached.body, nil
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s for %s", resp.StatusCode, resp.Status, url)
+		return nil, fmt.Errorf("HTTP %d: %s for %s", resp.StatusCode, resp.Status, rawURL)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", rawURL, err)
 	}
 
-	return ioutil.ReadAll(resp.Body)
+	c.cache.putFile(rawURL, &fileCacheEntry{
+		body:         body,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+		fetchedAt:    time.Now(),
+	})
+	return body, nil
 }
 
-func convertToGiteaAPIURL(repoURL, path, branch string) string {
+// parseRepoURL splits a repo URL like "https://host/owner/repo" into the
+// Gitea instance base URL and the owner/repo pair.
+func parseRepoURL(repoURL string) (baseURL, owner, repo string, err error) {
 	urlParts := strings.Split(strings.TrimRight(repoURL, "/"), "/")
-	baseURL := strings.Join(urlParts[:len(urlParts)-2], "/")
-	owner := urlParts[len(urlParts)-2]
-	repo := urlParts[len(urlParts)-1]
-
-	pathSegment := ""
-	if path != "" {
-		pathSegment = "/" + path
+	if len(urlParts) < 2 {
+		return "", "", "", fmt.Errorf("invalid repo URL %q", repoURL)
 	}
-	return fmt.Sprintf("%s/api/v1/repos/%s/%s/contents%s?ref=%s", baseURL, owner, repo, pathSegment, branch)
+	owner = urlParts[len(urlParts)-2]
+	repo = urlParts[len(urlParts)-1]
+	baseURL = strings.Join(urlParts[:len(urlParts)-2], "/")
+	return baseURL, owner, repo, nil
+}
+
+// rawFileURL builds the URL Gitea serves a file's raw bytes from.
+func rawFileURL(baseURL, owner, repo, path, branch string) string {
+	return fmt.Sprintf("%s/%s/%s/raw/branch/%s/%s", baseURL, owner, repo, branch, path)
 }
 
-func getGiteaDirectoryListing(repoURL, path, branch string) ([]GiteaFile, error) {
-	apiURL := convertToGiteaAPIURL(repoURL, path, branch)
-	body, err := fetchURL(apiURL)
+func main() {
+	var (
+		configPath               string
+		repoURL                  string
+		branch                   string
+		giteaToken               string
+		tlsInsecureSkipVerify    bool
+		maxConcurrentAPIRequests int64
+		cacheTTL                 time.Duration
+		cacheMaxEntries          int
+	)
+	flag.StringVar(&configPath, "config", "", "path to a YAML config file describing one or more site sources; if unset, a single Gitea source is built from the flags below")
+	flag.StringVar(&repoURL, "repo-url", "https://codeview.jio.indradhanus.com/indradhanus/sites", "Gitea repository URL to scan (used when -config is not set)")
+	flag.StringVar(&branch, "branch", "prod", "branch to scan (used when -config is not set)")
+	flag.StringVar(&giteaToken, "gitea-token", os.Getenv("GITEA_API_TOKEN"), "Gitea API token (used when -config is not set; defaults to $GITEA_API_TOKEN)")
+	flag.BoolVar(&tlsInsecureSkipVerify, "tls-insecure-skip-verify", false, "skip TLS certificate verification when talking to sources")
+	flag.Int64Var(&maxConcurrentAPIRequests, "max-concurrent-requests", 10, "maximum number of concurrent API requests per source")
+	flag.DurationVar(&cacheTTL, "cache-ttl", 5*time.Minute, "how long cached directory listings and files are trusted before revalidating")
+	flag.IntVar(&cacheMaxEntries, "cache-max-entries", 2000, "maximum number of entries kept in each source's response cache")
+	flag.Parse()
+
+	baseURL, owner, repo, err := parseRepoURL(repoURL)
 	if err != nil {
-		return nil, err
+		log.Fatalf("Error parsing -repo-url: %v", err)
 	}
+	legacySource := SourceConfig{Provider: "gitea", BaseURL: baseURL, Owner: owner, Repo: repo, Branch: branch, Token: giteaToken}
 
-	var files []GiteaFile
-	if err := json.Unmarshal(body, &files); err != nil {
-		// Gitea can return an object instead of an array for a single file view
-		// We can ignore this error as we are only interested in directories
-		return nil, nil
+	appCfg, err := loadAppConfig(configPath, legacySource)
+	if err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+	if configPath == "" {
+		appCfg.MaxConcurrentAPIRequests = maxConcurrentAPIRequests
+		appCfg.CacheTTL = cacheTTL
+		appCfg.CacheMaxEntries = cacheMaxEntries
+		appCfg.TLSInsecureSkipVerify = tlsInsecureSkipVerify
 	}
-	return files, nil
+
+	transport := &http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: appCfg.TLSInsecureSkipVerify,
+			MinVersion:         tls.VersionTLS12,
+		},
+	}
+
+	// Sources (and their response caches) are built once and shared across
+	// requests, so a second SSE connection can benefit from what the first
+	// one already fetched.
+	sources := make([]SiteSource, 0, len(appCfg.Sources))
+	for _, sc := range appCfg.Sources {
+		source, err := newSiteSource(sc, transport, appCfg.MaxConcurrentAPIRequests, appCfg.CacheTTL, appCfg.CacheMaxEntries)
+		if err != nil {
+			log.Fatalf("Error creating source %s/%s: %v", sc.Owner, sc.Repo, err)
+		}
+		sources = append(sources, source)
+	}
+	sourcePrefixes := make(map[string]string, len(appCfg.Sources))
+	for i, sc := range appCfg.Sources {
+		sourcePrefixes[sources[i].Name()] = sc.PathPrefix
+	}
+
+	http.HandleFunc("/api/data", func(w http.ResponseWriter, r *http.Request) {
+		dataHandler(w, r, sources, sourcePrefixes)
+	})
+	fmt.Println("Server is running on http://localhost:3001")
+	log.Fatal(http.ListenAndServe(":3001", nil))
 }
 
-func isSiteDirectory(items []GiteaFile) bool {
+// dataHandler subscribes the client to the current (or a freshly started)
+// scan session, replaying any events buffered since the client's
+// Last-Event-ID, then streams new named events as they're published.
+// Periodic ":keepalive" comments keep the connection alive through proxies
+// that would otherwise time out an idle response.
+func dataHandler(w http.ResponseWriter, r *http.Request, sources []SiteSource, sourcePrefixes map[string]string) {
+	log.Println("Received request for /api/data SSE stream")
+
+	// Set headers for SSE
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported!", http.StatusInternalServerError)
+		return
+	}
+
+	session := getOrStartSession(sources, sourcePrefixes)
+	subID, events, replay := session.subscribe(parseLastEventID(r.Header.Get("Last-Event-ID")))
+	defer session.unsubscribe(subID)
+
+	for _, ev := range replay {
+		writeSSEEvent(w, flusher, ev)
+	}
+	if session.isDone() {
+		log.Println("Scan already finished, closing stream.")
+		return
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	ctx := r.Context()
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			writeSSEEvent(w, flusher, ev)
+			if ev.event == "done" {
+				log.Println("Scan finished, closing stream.")
+				return
+			}
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+		case <-ctx.Done():
+			log.Println("Client disconnected.")
+			return
+		}
+	}
+}
+
+func isSiteDirectory(items []SourceEntry) bool {
 	for _, item := range items {
 		if item.Type == "file" && bmNodeFileRegex.MatchString(item.Name) {
 			return true
@@ -143,45 +395,61 @@ func isSiteDirectory(items []GiteaFile) bool {
 	return false
 }
 
-func processSiteDirectory(repoURL, sitePath, branch string, siteInfoChan chan<- SiteHostnameInfo) {
-	siteInfo := SiteHostnameInfo{SitePath: sitePath, Hostnames: []string{}, BMNodeFiles: []string{}, Errors: []string{}}
+func processSiteDirectory(ctx context.Context, source SiteSource, sitePath string, session *scanSession, tracker *progressTracker) {
+	siteInfo := SiteHostnameInfo{Source: source.Name(), SitePath: sitePath, Hostnames: []string{}, Nodes: []BMNode{}, BMNodeFiles: []string{}, Errors: []string{}}
 
-	items, err := getGiteaDirectoryListing(repoURL, sitePath, branch)
+	items, err := source.ListDir(ctx, sitePath)
 	if err != nil {
 		siteInfo.Errors = append(siteInfo.Errors, err.Error())
-		siteInfoChan <- siteInfo
+		publishSite(session, siteInfo, tracker)
 		return
 	}
 
 	for _, file := range items {
+		if ctx.Err() != nil {
+			return
+		}
 		if file.Type == "file" && bmNodeFileRegex.MatchString(file.Name) {
 			siteInfo.BMNodeFiles = append(siteInfo.BMNodeFiles, file.Name)
-			// Always construct the URL to ensure consistent hostname
-			fileURL := constructRawURL(repoURL, sitePath+"/"+file.Name, branch)
 
-			content, err := fetchURL(fileURL)
+			content, err := source.FetchFile(ctx, sitePath+"/"+file.Name)
+			if err != nil {
+				siteInfo.Errors = append(siteInfo.Errors, fmt.Sprintf("Error processing %s: %v", file.Name, err))
+				continue
+			}
+
+			node, err := parseBMNode(content)
 			if err != nil {
 				siteInfo.Errors = append(siteInfo.Errors, fmt.Sprintf("Error processing %s: %v", file.Name, err))
 				continue
 			}
+			siteInfo.Nodes = append(siteInfo.Nodes, node)
 
-			match := hostnameRegex.FindStringSubmatch(string(content))
-			if len(match) > 1 {
-				siteInfo.Hostnames = append(siteInfo.Hostnames, match[1])
+			if hostname, ok := node.Annotations[hostnameAnnotation]; ok && hostname != "" {
+				siteInfo.Hostnames = append(siteInfo.Hostnames, hostname)
 			} else {
 				siteInfo.Errors = append(siteInfo.Errors, fmt.Sprintf("No hostname annotation found in %s", file.Name))
 			}
 		}
 	}
-	siteInfoChan <- siteInfo
+	publishSite(session, siteInfo, tracker)
 }
 
-func discoverSitesRecursively(repoURL, currentPath, branch string, siteInfoChan chan<- SiteHostnameInfo, wg *sync.WaitGroup) {
+func discoverSitesRecursively(ctx context.Context, source SiteSource, currentPath string, session *scanSession, tracker *progressTracker, wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	items, err := getGiteaDirectoryListing(repoURL, currentPath, branch)
+	if ctx.Err() != nil {
+		return
+	}
+
+	items, err := source.ListDir(ctx, currentPath)
 	if err != nil {
-		log.Printf("Error getting directory listing for %s: %v", currentPath, err)
+		msg := fmt.Sprintf("Error getting directory listing for %s on %s: %v", currentPath, source.Name(), err)
+		log.Println(msg)
+		data, jsonErr := json.Marshal(map[string]string{"source": source.Name(), "path": currentPath, "error": msg})
+		if jsonErr == nil {
+			session.publish("error", data)
+		}
 		return
 	}
 
@@ -190,7 +458,9 @@ func discoverSitesRecursively(repoURL, currentPath, branch string, siteInfoChan
 		if pathKey == "" {
 			pathKey = "root"
 		}
-		processSiteDirectory(repoURL, pathKey, branch, siteInfoChan)
+		tracker.markDiscovered()
+		publishProgress(session, tracker)
+		processSiteDirectory(ctx, source, pathKey, session, tracker)
 	}
 
 	for _, subdir := range items {
@@ -200,22 +470,14 @@ func discoverSitesRecursively(repoURL, currentPath, branch string, siteInfoChan
 				dirPath = currentPath + "/" + subdir.Name
 			}
 			wg.Add(1)
-			go discoverSitesRecursively(repoURL, dirPath, branch, siteInfoChan, wg)
+			go discoverSitesRecursively(ctx, source, dirPath, session, tracker, wg)
 		}
 	}
 }
 
-func constructRawURL(repoURL, filePath, branch string) string {
-	urlParts := strings.Split(strings.TrimRight(repoURL, "/"), "/")
-	owner := urlParts[len(urlParts)-2]
-	repo := urlParts[len(urlParts)-1]
-	baseURL := strings.Join(urlParts[:len(urlParts)-2], "/")
-	return fmt.Sprintf("%s/%s/%s/raw/branch/%s/%s", baseURL, owner, repo, branch, filePath)
-}
-
-func extractHostnamesFromGiteaRepository(repoURL, branch string, siteInfoChan chan<- SiteHostnameInfo) {
+func extractHostnamesFromSource(ctx context.Context, source SiteSource, pathPrefix string, session *scanSession, tracker *progressTracker) {
 	var wg sync.WaitGroup
 	wg.Add(1)
-	go discoverSitesRecursively(repoURL, "", branch, siteInfoChan, &wg)
+	go discoverSitesRecursively(ctx, source, pathPrefix, session, tracker, &wg)
 	wg.Wait()
-}
\ No newline at end of file
+}
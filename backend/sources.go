@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SiteSource abstracts over the different Git hosting providers a sites
+// repository might live in, so extractHostnamesFromSource doesn't need to
+// know whether it's talking to Gitea, GitHub, GitLab, or a generic raw HTTP
+// endpoint.
+type SiteSource interface {
+	// Name identifies the source in SiteHostnameInfo.Source, e.g.
+	// "gitea:indradhanus/sites@prod".
+	Name() string
+	ListDir(ctx context.Context, path string) ([]SourceEntry, error)
+	FetchFile(ctx context.Context, path string) ([]byte, error)
+}
+
+// SourceEntry is a provider-agnostic directory entry.
+type SourceEntry struct {
+	Name string
+	Type string // "file" or "dir"
+}
+
+// SourceConfig describes one configured site source.
+type SourceConfig struct {
+	Provider   string `yaml:"provider"` // "gitea", "github", "gitlab", or "raw"
+	BaseURL    string `yaml:"baseURL"`
+	Owner      string `yaml:"owner"`
+	Repo       string `yaml:"repo"`
+	Branch     string `yaml:"branch"`
+	PathPrefix string `yaml:"pathPrefix"`
+	Token      string `yaml:"token"`
+}
+
+// AppConfig is the top-level configuration, loadable from a YAML file and
+// overridable by the CLI flags handled in main().
+type AppConfig struct {
+	Sources                  []SourceConfig `yaml:"sources"`
+	MaxConcurrentAPIRequests int64          `yaml:"maxConcurrentApiRequests"`
+	CacheTTL                 time.Duration  `yaml:"cacheTTL"`
+	CacheMaxEntries          int            `yaml:"cacheMaxEntries"`
+	TLSInsecureSkipVerify    bool           `yaml:"tlsInsecureSkipVerify"`
+}
+
+// loadAppConfig reads a YAML config file from path describing one or more
+// site sources. If path is empty, it falls back to a single-source config
+// built from legacy, preserving the tool's original single-repo behavior
+// when no config file is given.
+func loadAppConfig(path string, legacy SourceConfig) (AppConfig, error) {
+	cfg := AppConfig{
+		MaxConcurrentAPIRequests: 10,
+		CacheTTL:                 5 * time.Minute,
+		CacheMaxEntries:          2000,
+	}
+
+	if path == "" {
+		cfg.Sources = []SourceConfig{legacy}
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("reading config %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	if len(cfg.Sources) == 0 {
+		return cfg, fmt.Errorf("config %s defines no sources", path)
+	}
+	return cfg, nil
+}
+
+// newSiteSource builds the SiteSource for sc. Only the "gitea" provider is
+// implemented today; "github", "gitlab", and "raw" are recognized so config
+// files can already name them, but fail fast until their clients exist.
+func newSiteSource(sc SourceConfig, transport *http.Transport, maxConcurrentRequests int64, cacheTTL time.Duration, cacheMaxEntries int) (SiteSource, error) {
+	switch sc.Provider {
+	case "", "gitea":
+		return NewGiteaClient(sc, transport, maxConcurrentRequests, cacheTTL, cacheMaxEntries)
+	default:
+		return nil, fmt.Errorf("unsupported provider %q for source %s/%s", sc.Provider, sc.Owner, sc.Repo)
+	}
+}
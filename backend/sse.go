@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	heartbeatInterval   = 15 * time.Second
+	sessionBufferEvents = 4096
+	sessionIdleGrace    = 5 * time.Second
+)
+
+// sseEvent is one named, identified Server-Sent Event.
+type sseEvent struct {
+	id    uint64
+	event string
+	data  []byte
+}
+
+// progressCounters is the payload of a "progress" event.
+type progressCounters struct {
+	Discovered int64 `json:"discovered"`
+	Processed  int64 `json:"processed"`
+	Pending    int64 `json:"pending"`
+}
+
+// progressTracker counts how many site directories a scan has discovered
+// versus fully processed, so dataHandler can report a live progress bar.
+type progressTracker struct {
+	discovered int64
+	processed  int64
+}
+
+func (t *progressTracker) markDiscovered() { atomic.AddInt64(&t.discovered, 1) }
+func (t *progressTracker) markProcessed()  { atomic.AddInt64(&t.processed, 1) }
+
+func (t *progressTracker) snapshot() progressCounters {
+	discovered := atomic.LoadInt64(&t.discovered)
+	processed := atomic.LoadInt64(&t.processed)
+	return progressCounters{Discovered: discovered, Processed: processed, Pending: discovered - processed}
+}
+
+// scanSession is one background site-discovery scan shared by every client
+// currently watching it. Published events are kept in a ring buffer so a
+// reconnecting client can resume from its Last-Event-ID instead of missing
+// whatever ran while it was disconnected. Once the last subscriber goes
+// away, the scan is cancelled after sessionIdleGrace - generalizing the
+// per-connection cancellation from a single client's context to "nobody is
+// listening anymore".
+type scanSession struct {
+	mu          sync.Mutex
+	buffer      []sseEvent
+	bufferCap   int
+	nextEventID uint64
+	subscribers map[uint64]chan sseEvent
+	nextSubID   uint64
+	done        bool
+	idleTimer   *time.Timer
+
+	cleanup   func()
+	idleGrace time.Duration
+}
+
+func newScanSession(cleanup func(), bufferCap int, idleGrace time.Duration) *scanSession {
+	return &scanSession{
+		bufferCap:   bufferCap,
+		subscribers: make(map[uint64]chan sseEvent),
+		cleanup:     cleanup,
+		idleGrace:   idleGrace,
+	}
+}
+
+// publish appends event to the ring buffer and fans it out to every current
+// subscriber. A subscriber whose channel is full simply misses the live
+// event; it can still catch up via Last-Event-ID as long as the ring buffer
+// still holds it.
+func (s *scanSession) publish(event string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextEventID++
+	ev := sseEvent{id: s.nextEventID, event: event, data: data}
+	s.buffer = append(s.buffer, ev)
+	if len(s.buffer) > s.bufferCap {
+		s.buffer = s.buffer[len(s.buffer)-s.bufferCap:]
+	}
+	for _, ch := range s.subscribers {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+func (s *scanSession) markDone() {
+	s.mu.Lock()
+	s.done = true
+	s.mu.Unlock()
+}
+
+// isDone reports whether the scan has already published its "done" event.
+// A subscriber that attaches after this point (but within the idle grace
+// period) has nothing further to wait for, even if the "done" event itself
+// has since scrolled out of the ring buffer.
+func (s *scanSession) isDone() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done
+}
+
+// subscribe registers a new listener, replaying any buffered events newer
+// than lastEventID and cancelling a pending idle-shutdown timer if one was
+// running.
+func (s *scanSession) subscribe(lastEventID uint64) (id uint64, events chan sseEvent, replay []sseEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.idleTimer != nil {
+		s.idleTimer.Stop()
+		s.idleTimer = nil
+	}
+
+	for _, ev := range s.buffer {
+		if ev.id > lastEventID {
+			replay = append(replay, ev)
+		}
+	}
+
+	s.nextSubID++
+	id = s.nextSubID
+	events = make(chan sseEvent, 32)
+	s.subscribers[id] = events
+	return id, events, replay
+}
+
+// unsubscribe removes a listener. Once the last one is gone, the scan is
+// cancelled after idleGrace, giving a quick client reconnect a chance to
+// attach to the same in-flight scan instead of starting a new one.
+func (s *scanSession) unsubscribe(id uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.subscribers, id)
+	if len(s.subscribers) == 0 {
+		s.idleTimer = time.AfterFunc(s.idleGrace, s.cleanup)
+	}
+}
+
+var (
+	sessionMu      sync.Mutex
+	currentSession *scanSession
+)
+
+// getOrStartSession returns the scan currently in progress (or recently
+// finished and still within its idle grace period), starting a new one if
+// none is active.
+func getOrStartSession(sources []SiteSource, sourcePrefixes map[string]string) *scanSession {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+
+	if currentSession != nil {
+		return currentSession
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var session *scanSession
+	cleanup := func() {
+		cancel()
+		sessionMu.Lock()
+		if currentSession == session {
+			currentSession = nil
+		}
+		sessionMu.Unlock()
+	}
+	session = newScanSession(cleanup, sessionBufferEvents, sessionIdleGrace)
+	currentSession = session
+
+	go runScan(ctx, session, sources, sourcePrefixes)
+
+	return session
+}
+
+// runScan walks every configured source, publishing a "site" event (plus a
+// "progress" event with discovered/processed/pending counters) per site
+// directory found and an "error" event per directory listing failure, then
+// a final "done" event.
+func runScan(ctx context.Context, session *scanSession, sources []SiteSource, sourcePrefixes map[string]string) {
+	tracker := &progressTracker{}
+
+	var wg sync.WaitGroup
+	for _, source := range sources {
+		wg.Add(1)
+		go func(source SiteSource) {
+			defer wg.Done()
+			extractHostnamesFromSource(ctx, source, sourcePrefixes[source.Name()], session, tracker)
+		}(source)
+	}
+	wg.Wait()
+
+	session.publish("done", []byte("{}"))
+	session.markDone()
+}
+
+func publishSite(session *scanSession, siteInfo SiteHostnameInfo, tracker *progressTracker) {
+	data, err := json.Marshal(siteInfo)
+	if err != nil {
+		log.Printf("Error marshalling JSON: %v", err)
+		return
+	}
+	session.publish("site", data)
+	tracker.markProcessed()
+	publishProgress(session, tracker)
+}
+
+func publishProgress(session *scanSession, tracker *progressTracker) {
+	data, err := json.Marshal(tracker.snapshot())
+	if err != nil {
+		log.Printf("Error marshalling progress: %v", err)
+		return
+	}
+	session.publish("progress", data)
+}
+
+// writeSSEEvent writes ev to w in the "id: / event: / data:" form and
+// flushes it immediately.
+func writeSSEEvent(w http.ResponseWriter, flusher http.Flusher, ev sseEvent) {
+	fmt.Fprintf(w, "id: %d\n", ev.id)
+	fmt.Fprintf(w, "event: %s\n", ev.event)
+	fmt.Fprintf(w, "data: %s\n\n", ev.data)
+	flusher.Flush()
+}
+
+// parseLastEventID parses the Last-Event-ID request header, defaulting to 0
+// (replay everything buffered) when it's absent or malformed.
+func parseLastEventID(header string) uint64 {
+	id, err := strconv.ParseUint(header, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}